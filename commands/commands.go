@@ -0,0 +1,119 @@
+// Package commands implements the slash-command parser used by keyEnter to
+// intercept inputs beginning with "/" before they reach Gemini.
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Host is the subset of model state a Command needs to act on. main's model
+// implements this.
+type Host interface {
+	SetModel(name string) error
+	SetSystemInstruction(text string) error
+	ClearChat()
+	SaveConversation(name string) error
+	LoadConversation(name string) error
+	ShowHelp(text string)
+}
+
+// Command is a single slash command: /<Name> <args...>.
+type Command struct {
+	Name  string
+	Usage string
+	Run   func(h Host, args []string) tea.Cmd
+}
+
+var registry = []Command{
+	{
+		Name:  "model",
+		Usage: "/model <name> — switch the active model",
+		Run: func(h Host, args []string) tea.Cmd {
+			if len(args) == 0 {
+				h.ShowHelp("usage: /model <name>")
+				return nil
+			}
+			if err := h.SetModel(args[0]); err != nil {
+				h.ShowHelp(fmt.Sprintf("error: %v", err))
+			}
+			return nil
+		},
+	},
+	{
+		Name:  "system",
+		Usage: "/system <text> — set the system instruction",
+		Run: func(h Host, args []string) tea.Cmd {
+			if len(args) == 0 {
+				h.ShowHelp("usage: /system <text>")
+				return nil
+			}
+			if err := h.SetSystemInstruction(strings.Join(args, " ")); err != nil {
+				h.ShowHelp(fmt.Sprintf("error: %v", err))
+			}
+			return nil
+		},
+	},
+	{
+		Name:  "clear",
+		Usage: "/clear — wipe the current conversation",
+		Run: func(h Host, args []string) tea.Cmd {
+			h.ClearChat()
+			return nil
+		},
+	},
+	{
+		Name:  "save",
+		Usage: "/save <name> — save the conversation under a name",
+		Run: func(h Host, args []string) tea.Cmd {
+			if err := h.SaveConversation(strings.Join(args, " ")); err != nil {
+				h.ShowHelp(fmt.Sprintf("error: %v", err))
+			}
+			return nil
+		},
+	},
+	{
+		Name:  "load",
+		Usage: "/load <name> — resume a saved conversation",
+		Run: func(h Host, args []string) tea.Cmd {
+			if len(args) == 0 {
+				h.ShowHelp("usage: /load <name>")
+				return nil
+			}
+			if err := h.LoadConversation(strings.Join(args, " ")); err != nil {
+				h.ShowHelp(fmt.Sprintf("error: %v", err))
+			}
+			return nil
+		},
+	},
+	{
+		Name:  "help",
+		Usage: "/help — list available commands",
+		Run: func(h Host, args []string) tea.Cmd {
+			h.ShowHelp(Help())
+			return nil
+		},
+	},
+}
+
+// Lookup returns the command registered under name, if any.
+func Lookup(name string) (Command, bool) {
+	for _, c := range registry {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
+// Help renders a cheat sheet of every registered command.
+func Help() string {
+	var b strings.Builder
+	b.WriteString("available commands:\n")
+	for _, c := range registry {
+		b.WriteString("  " + c.Usage + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,60 @@
+// Package convlist renders a picker over saved conversations using a bubbles
+// list.Model, for the conversation-list app state.
+package convlist
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+
+	"github.com/anthnnygiang/gemini/store"
+)
+
+// Item adapts a store.Conversation to list.Item.
+type Item struct {
+	Conversation store.Conversation
+}
+
+func (i Item) Title() string { return i.Conversation.Name }
+
+func (i Item) Description() string {
+	turns := len(i.Conversation.Display)
+	return fmt.Sprintf("%s · %d messages · updated %s",
+		i.Conversation.Model, turns, i.Conversation.UpdatedAt.Format("Jan 2 15:04"))
+}
+
+func (i Item) FilterValue() string { return i.Conversation.Name }
+
+// New builds a list.Model populated with the given conversations, newest
+// first (callers are expected to pass them already sorted by store.List).
+func New(conversations []store.Conversation, width, height int) list.Model {
+	items := make([]list.Item, len(conversations))
+	for i, c := range conversations {
+		items[i] = Item{Conversation: c}
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), width, height)
+	l.Title = "Conversations"
+	l.SetShowHelp(true)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "resume")),
+			key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new")),
+			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rename")),
+			key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+			key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+		}
+	}
+	return l
+}
+
+// SelectedConversation returns the conversation backing the currently
+// highlighted item, if any.
+func SelectedConversation(l list.Model) (store.Conversation, bool) {
+	item, ok := l.SelectedItem().(Item)
+	if !ok {
+		return store.Conversation{}, false
+	}
+	return item.Conversation, true
+}
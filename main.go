@@ -5,23 +5,38 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 	"google.golang.org/genai"
+
+	"github.com/anthnnygiang/gemini/commands"
+	"github.com/anthnnygiang/gemini/convlist"
+	"github.com/anthnnygiang/gemini/pricing"
+	"github.com/anthnnygiang/gemini/statusbar"
+	"github.com/anthnnygiang/gemini/store"
 )
 
 const GAP = "\n\n"
 const SYSTEM_INSTRUCTION = "answer concisely."
 const MODEL = "gemini-2.5-flash"
 const GOOGLE_CLI = "GOOGLE_CLI"
+const taMinHeight = 2
+const taMaxHeight = 10
 
 type errMsg error
 
@@ -30,21 +45,80 @@ type streamMsg struct {
 	part  string
 }
 
+// usageStats is the token usage reported on a stream's final chunk.
+type usageStats struct {
+	promptTokens    int
+	candidateTokens int
+	totalTokens     int
+}
+
+// streamDoneMsg signals that a reply's stream has finished, either by
+// reaching the end or by being cancelled.
+type streamDoneMsg struct {
+	index int
+	usage usageStats
+}
+
+// appState selects which view Update/View dispatch to.
+type appState int
+
+const (
+	stateChat appState = iota
+	stateConversationList
+)
+
 type model struct {
 	viewport    viewport.Model
 	history     []*genai.Content
 	display     []string // TODO: derive display from history
+	rawDisplay  []string // raw (un-rendered) text per turn; "" for user turns
+	markdown    bool
+	multiline   bool
 	textarea    textarea.Model
-	promptStyle lipgloss.Style
-	chatStyle   lipgloss.Style
-	chat        *genai.Chat
-	next        tea.Cmd
-	ctx         context.Context
-	err         error
-	log         *os.File
+
+	activeModel       string
+	systemInstruction string
+	promptStyle       lipgloss.Style
+	chatStyle         lipgloss.Style
+	client            *genai.Client
+	chat              *genai.Chat
+	next              tea.Cmd
+	ctx               context.Context
+	err               error
+	log               *os.File
+
+	appState appState
+	store    *store.Store
+
+	conversationID   string
+	conversationName string
+
+	convList    list.Model
+	renaming    bool
+	renameInput textinput.Model
+
+	promptHistory []string
+	historyIndex  int // index into promptHistory; == len(promptHistory) means "on the draft"
+	draftPrompt   string
+
+	statusbar              statusbar.Model
+	prices                 pricing.Table
+	lastUsage              usageStats
+	sessionPromptTokens    int
+	sessionCandidateTokens int
+	sessionCost            float64
+
+	spinner         spinner.Model
+	waitingForReply bool
+	cancelling      bool
+	stopSignal      chan struct{}
+	streamCancel    context.CancelFunc
 }
 
 func main() {
+	noMarkdown := flag.Bool("no-markdown", false, "render model replies as plain text instead of markdown")
+	flag.Parse()
+
 	// clear file
 	os.WriteFile("debug.log", []byte{}, 0644)
 	f, err := tea.LogToFile("debug.log", "debug")
@@ -53,13 +127,13 @@ func main() {
 		os.Exit(1)
 	}
 	defer f.Close()
-	m := initialModel(f)
+	m := initialModel(f, !*noMarkdown)
 	p := tea.NewProgram(m)
 	if _, err := p.Run(); err != nil {
 		log.Fatal(err)
 	}
 }
-func initialModel(f *os.File) tea.Model {
+func initialModel(f *os.File, markdown bool) tea.Model {
 	GEMINI_API_KEY := os.Getenv(GOOGLE_CLI)
 	if GEMINI_API_KEY == "" {
 		log.Fatal("missing 'GOOGLE_CLI' env variable.")
@@ -79,6 +153,24 @@ func initialModel(f *os.File) tea.Model {
 	}
 	chatClient, _ := client.Chats.Create(ctx, MODEL, &chatConfig, chatHistory)
 
+	convStore, err := store.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	promptHistory, err := convStore.LoadPromptHistory()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		log.Fatal(err)
+	}
+	prices, err := pricing.Load(filepath.Join(configDir, "gemini", "pricing.yaml"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	ta := textarea.New()
 	ta.Placeholder = "prompt.."
 	ta.Focus()
@@ -101,21 +193,43 @@ func initialModel(f *os.File) tea.Model {
 		)}
 
 	ta.KeyMap.InsertNewline.SetEnabled(false)
-	ta.KeyMap.LineNext.SetEnabled(false) // TODO: change up/down keypress to navigate history
-	ta.KeyMap.LinePrevious.SetEnabled(false)
+	// LineNext/LinePrevious stay enabled so up/down move the cursor within a
+	// multi-line prompt; Update only treats them as history navigation once
+	// the cursor is already on the textarea's first/last line.
+
+	ri := textinput.New()
+	ri.Placeholder = "new name.."
+
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
 
 	return model{
-		textarea:    ta,
-		history:     chatHistory,
-		display:     displayHistory,
-		viewport:    vp,
-		promptStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("4")),
-		chatStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
-		chat:        chatClient,
-		ctx:         ctx,
-		err:         nil,
-		next:        nil,
-		log:         f,
+		textarea:          ta,
+		history:           chatHistory,
+		display:           displayHistory,
+		rawDisplay:        []string{},
+		markdown:          markdown,
+		viewport:          vp,
+		activeModel:       MODEL,
+		systemInstruction: SYSTEM_INSTRUCTION,
+		promptStyle:       lipgloss.NewStyle().Foreground(lipgloss.Color("4")),
+		chatStyle:         lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+		client:            client,
+		chat:              chatClient,
+		ctx:               ctx,
+		err:               nil,
+		next:              nil,
+		log:               f,
+		appState:          stateChat,
+		store:             convStore,
+		conversationID:    store.NewID(),
+		conversationName:  "new conversation",
+		renameInput:       ri,
+		spinner:           sp,
+		promptHistory:     promptHistory,
+		historyIndex:      len(promptHistory),
+		statusbar:         statusbar.New(),
+		prices:            prices,
 	}
 }
 
@@ -124,6 +238,18 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.appState == stateConversationList {
+		return m.updateConversationList(msg)
+	}
+	return m.updateChat(msg)
+}
+
+func (m model) updateChat(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Capture the cursor's line before the textarea consumes up/down and
+	// moves it, so we can tell it was already at the first/last line.
+	wasAtFirstLine := m.textarea.Line() == 0
+	wasAtLastLine := m.textarea.Line() == lastTextareaLine(m.textarea)
+
 	var tiCmd tea.Cmd
 	var vpCmd tea.Cmd
 	// update textarea and viewport components
@@ -136,37 +262,560 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.windowSizeMsg(msg)
 
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyEsc, tea.KeyCtrlC:
+		switch msg.String() {
+		case "esc":
 			return m.keyEsc()
-		case tea.KeyEnter:
+		case "ctrl+c":
+			if m.waitingForReply {
+				return m.keyCtrlC()
+			}
+			return m.keyEsc()
+		case "shift+enter":
+			m.insertNewline()
+			return m, tea.Batch(tiCmd, vpCmd)
+		case "ctrl+s":
 			return m.keyEnter()
+		case "enter":
+			if m.multiline {
+				m.insertNewline()
+				return m, tea.Batch(tiCmd, vpCmd)
+			}
+			return m.keyEnter()
+		case "ctrl+l":
+			return m.openConversationList()
+		case "ctrl+g":
+			return m.toggleMarkdown()
+		case "ctrl+e":
+			return m.openEditor()
+		case "up":
+			if wasAtFirstLine {
+				return m.historyUp()
+			}
+		case "down":
+			if wasAtLastLine {
+				return m.historyDown()
+			}
 		}
 
 	case streamMsg:
 		return m.streamMsg(msg)
 
+	case streamDoneMsg:
+		return m.streamDoneMsg(msg)
+
+	case editorFinishedMsg:
+		return m.editorFinishedMsg(msg)
+
+	case spinner.TickMsg:
+		if !m.waitingForReply {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
 	case errMsg:
 		return m.errMsg()
 	}
 	return m, tea.Batch(tiCmd, vpCmd)
 }
 
+// updateConversationList drives the conversation picker: browsing, resuming,
+// starting a new conversation, renaming, and deleting.
+func (m model) updateConversationList(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.renaming {
+		return m.updateRename(msg)
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.convList.SetSize(msg.Width, msg.Height)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			m.appState = stateChat
+			return m, nil
+		case "n":
+			return m.newConversation()
+		case "enter":
+			return m.resumeSelectedConversation()
+		case "r":
+			if c, ok := convlist.SelectedConversation(m.convList); ok {
+				m.renaming = true
+				m.renameInput.SetValue(c.Name)
+				m.renameInput.Focus()
+				return m, textinput.Blink
+			}
+			return m, nil
+		case "d":
+			return m.deleteSelectedConversation()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.convList, cmd = m.convList.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateRename(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.renaming = false
+			return m, nil
+		case tea.KeyEnter:
+			if c, ok := convlist.SelectedConversation(m.convList); ok {
+				if err := m.store.Rename(c.ID, m.renameInput.Value()); err != nil {
+					m.err = err
+				}
+			}
+			m.renaming = false
+			return m.refreshConversationList()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.renameInput, cmd = m.renameInput.Update(msg)
+	return m, cmd
+}
+
+// openConversationList saves the active conversation (if it has any turns)
+// and switches to the conversation-list app state.
+func (m model) openConversationList() (tea.Model, tea.Cmd) {
+	m.saveConversation()
+	m.appState = stateConversationList
+	return m.refreshConversationList()
+}
+
+func (m model) refreshConversationList() (tea.Model, tea.Cmd) {
+	conversations, err := m.store.List()
+	if err != nil {
+		m.err = err
+		conversations = nil
+	}
+	m.convList = convlist.New(conversations, m.viewport.Width, m.viewport.Height)
+	return m, nil
+}
+
+func (m model) resumeSelectedConversation() (tea.Model, tea.Cmd) {
+	c, ok := convlist.SelectedConversation(m.convList)
+	if !ok {
+		return m, nil
+	}
+	if err := m.loadConversation(c); err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.appState = stateChat
+	return m, nil
+}
+
+// loadConversation recreates m.chat from a saved conversation's history and
+// rehydrates the display buffers. Shared by the conversation list's resume
+// action and the /load slash command.
+func (m *model) loadConversation(c store.Conversation) error {
+	if m.waitingForReply {
+		return fmt.Errorf("cannot load a conversation while a reply is streaming; wait for it to finish or press ctrl+c to cancel")
+	}
+	chatConfig := genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText(c.SystemInstruction, genai.RoleModel),
+	}
+	chatClient, err := m.client.Chats.Create(m.ctx, c.Model, &chatConfig, c.History)
+	if err != nil {
+		return err
+	}
+
+	m.conversationID = c.ID
+	m.conversationName = c.Name
+	m.activeModel = c.Model
+	m.systemInstruction = c.SystemInstruction
+	m.history = c.History
+	m.display = c.Display
+	m.rawDisplay = c.RawDisplay
+	m.chat = chatClient
+	m.viewport.SetContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(strings.Join(m.display, "\n")))
+	m.viewport.GotoBottom()
+	return nil
+}
+
+// recreateChat rebuilds m.chat against the current activeModel and
+// systemInstruction, preserving history. Used whenever either changes.
+func (m *model) recreateChat() error {
+	if m.waitingForReply {
+		return fmt.Errorf("cannot switch while a reply is streaming; wait for it to finish or press ctrl+c to cancel")
+	}
+	chatConfig := genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText(m.systemInstruction, genai.RoleModel),
+	}
+	chatClient, err := m.client.Chats.Create(m.ctx, m.activeModel, &chatConfig, m.history)
+	if err != nil {
+		return err
+	}
+	m.chat = chatClient
+	return nil
+}
+
+// SetModel implements commands.Host.
+func (m *model) SetModel(name string) error {
+	previous := m.activeModel
+	m.activeModel = name
+	if err := m.recreateChat(); err != nil {
+		m.activeModel = previous
+		return err
+	}
+	return nil
+}
+
+// SetSystemInstruction implements commands.Host.
+func (m *model) SetSystemInstruction(text string) error {
+	previous := m.systemInstruction
+	m.systemInstruction = text
+	if err := m.recreateChat(); err != nil {
+		m.systemInstruction = previous
+		return err
+	}
+	return nil
+}
+
+// ClearChat implements commands.Host.
+func (m *model) ClearChat() {
+	if m.waitingForReply {
+		m.ShowHelp("cannot clear while a reply is streaming; wait for it to finish or press ctrl+c to cancel")
+		return
+	}
+	m.history = []*genai.Content{}
+	m.display = []string{}
+	m.rawDisplay = []string{}
+	m.viewport.SetContent("")
+	m.recreateChat()
+}
+
+// SaveConversation implements commands.Host.
+func (m *model) SaveConversation(name string) error {
+	if name != "" {
+		m.conversationName = name
+	}
+	m.saveConversation()
+	return nil
+}
+
+// LoadConversation implements commands.Host.
+func (m *model) LoadConversation(name string) error {
+	conversations, err := m.store.List()
+	if err != nil {
+		return err
+	}
+	for _, c := range conversations {
+		if c.Name == name {
+			return m.loadConversation(c)
+		}
+	}
+	return fmt.Errorf("no conversation named %q", name)
+}
+
+// runCommand parses a "/name arg..." input and dispatches it through the
+// commands registry instead of sending it to Gemini.
+func (m model) runCommand(input string) (tea.Model, tea.Cmd) {
+	fields := strings.Fields(input)
+	name := strings.TrimPrefix(fields[0], "/")
+	args := fields[1:]
+
+	cmd, ok := commands.Lookup(name)
+	if !ok {
+		m.ShowHelp(fmt.Sprintf("unknown command: /%s (try /help)", name))
+		m.textarea.Reset()
+		m.resetTextareaHeight()
+		return m, nil
+	}
+
+	teaCmd := cmd.Run(&m, args)
+	m.textarea.Reset()
+	m.resetTextareaHeight()
+	return m, teaCmd
+}
+
+// ShowHelp implements commands.Host, appending text as a system line in the
+// viewport rather than sending it to Gemini.
+func (m *model) ShowHelp(text string) {
+	m.display = append(m.display, text)
+	m.rawDisplay = append(m.rawDisplay, "")
+	m.viewport.SetContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(strings.Join(m.display, "\n")))
+	m.viewport.GotoBottom()
+}
+
+func (m model) newConversation() (tea.Model, tea.Cmd) {
+	m.conversationID = store.NewID()
+	m.conversationName = "new conversation"
+	m.history = []*genai.Content{}
+	m.display = []string{}
+	m.rawDisplay = []string{}
+	m.appState = stateChat
+	m.viewport.SetContent("")
+	return m, nil
+}
+
+func (m model) deleteSelectedConversation() (tea.Model, tea.Cmd) {
+	c, ok := convlist.SelectedConversation(m.convList)
+	if !ok {
+		return m, nil
+	}
+	if err := m.store.Delete(c.ID); err != nil {
+		m.err = err
+	}
+	return m.refreshConversationList()
+}
+
+// saveConversation persists the current chat to the store, provided it has
+// at least one turn.
+func (m model) saveConversation() {
+	if len(m.display) == 0 {
+		return
+	}
+	c := &store.Conversation{
+		ID:                m.conversationID,
+		Name:              m.conversationName,
+		Model:             m.activeModel,
+		SystemInstruction: m.systemInstruction,
+		History:           m.history,
+		Display:           m.display,
+		RawDisplay:        m.rawDisplay,
+	}
+	if err := m.store.Save(c); err != nil {
+		m.log.WriteString(fmt.Sprintf("save conversation: %v\n", err))
+	}
+}
+
+// lastTextareaLine returns the 0-based index of a textarea's last line.
+func lastTextareaLine(ta textarea.Model) int {
+	return strings.Count(ta.Value(), "\n")
+}
+
+// historyUp recalls the previous prompt, stashing the in-progress draft the
+// first time the user navigates away from it.
+func (m model) historyUp() (tea.Model, tea.Cmd) {
+	if m.historyIndex == 0 {
+		return m, nil // already at the oldest prompt
+	}
+	if m.historyIndex == len(m.promptHistory) {
+		m.draftPrompt = m.textarea.Value()
+	}
+	m.historyIndex--
+	m.setTextareaFromHistory(m.promptHistory[m.historyIndex])
+	return m, nil
+}
+
+// historyDown recalls the next prompt, or restores the stashed draft once
+// the user scrolls back past the newest entry.
+func (m model) historyDown() (tea.Model, tea.Cmd) {
+	if m.historyIndex >= len(m.promptHistory) {
+		return m, nil // already on the draft
+	}
+	m.historyIndex++
+	if m.historyIndex == len(m.promptHistory) {
+		m.setTextareaFromHistory(m.draftPrompt)
+		return m, nil
+	}
+	m.setTextareaFromHistory(m.promptHistory[m.historyIndex])
+	return m, nil
+}
+
+// setTextareaFromHistory loads recalled text into the textarea, growing it
+// to fit multi-line entries the same way insertNewline does.
+func (m *model) setTextareaFromHistory(text string) {
+	m.textarea.SetValue(text)
+	m.multiline = strings.Contains(text, "\n")
+	lines := strings.Count(text, "\n") + 1
+	switch {
+	case lines > taMaxHeight:
+		lines = taMaxHeight
+	case lines < taMinHeight:
+		lines = taMinHeight
+	}
+	m.textarea.SetHeight(lines)
+}
+
+// insertNewline switches the textarea into multiline mode, inserts a newline
+// at the cursor, and grows the textarea by one row, up to taMaxHeight rows.
+func (m *model) insertNewline() {
+	m.multiline = true
+	m.textarea.InsertString("\n") // insert at the cursor, not the end of the buffer
+	if height := m.textarea.Height(); height < taMaxHeight {
+		m.textarea.SetHeight(height + 1)
+	}
+}
+
+// resetTextareaHeight collapses the textarea back to its single-line size
+// and clears multiline mode, called after a prompt is sent.
+func (m *model) resetTextareaHeight() {
+	m.multiline = false
+	m.textarea.SetHeight(taMinHeight)
+}
+
+// editorFinishedMsg carries the result of an external $EDITOR session back
+// into the program once tea.ExecProcess returns control to bubbletea.
+type editorFinishedMsg struct {
+	path string
+	err  error
+}
+
+// openEditor suspends bubbletea and opens $EDITOR on a temp file seeded with
+// the textarea's current contents, mirroring lmcli's editorTarget pattern.
+func (m model) openEditor() (tea.Model, tea.Cmd) {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	tmp, err := os.CreateTemp("", "gemini-prompt-*.md")
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	path := tmp.Name()
+	if _, err := tmp.WriteString(m.textarea.Value()); err != nil {
+		tmp.Close()
+		m.err = err
+		return m, nil
+	}
+	tmp.Close()
+
+	cmd := exec.Command(editor, path)
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorFinishedMsg{path: path, err: err}
+	})
+}
+
+// editorFinishedMsg loads the edited buffer back into the textarea once
+// $EDITOR exits.
+func (m model) editorFinishedMsg(msg editorFinishedMsg) (tea.Model, tea.Cmd) {
+	defer os.Remove(msg.path)
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	data, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	content := strings.TrimRight(string(data), "\n")
+	m.textarea.SetValue(content)
+	if strings.Contains(content, "\n") {
+		m.multiline = true
+		lines := strings.Count(content, "\n") + 1
+		if lines > taMaxHeight {
+			lines = taMaxHeight
+		}
+		if lines < taMinHeight {
+			lines = taMinHeight
+		}
+		m.textarea.SetHeight(lines)
+	}
+	return m, textarea.Blink
+}
+
 // View renders the UI of the program
 func (m model) View() string {
+	if m.appState == stateConversationList {
+		if m.renaming {
+			return fmt.Sprintf("%s\n\nrename to: %s", m.convList.View(), m.renameInput.View())
+		}
+		return m.convList.View()
+	}
+	status := ""
+	switch {
+	case m.cancelling:
+		status = "Cancelling…\n"
+	case m.waitingForReply:
+		status = m.spinner.View() + " thinking…\n"
+	}
+	bar := m.statusbar.View(m.viewport.Width, statusbar.Stats{
+		PromptTokens:    m.lastUsage.promptTokens,
+		CandidateTokens: m.lastUsage.candidateTokens,
+		SessionTokens:   m.sessionPromptTokens + m.sessionCandidateTokens,
+		Cost:            m.prices.Cost(m.activeModel, m.lastUsage.promptTokens, m.lastUsage.candidateTokens),
+		SessionCost:     m.sessionCost,
+	})
 	return fmt.Sprintf(
-		"%s%s%s",
+		"%s%s%s%s%s",
 		m.viewport.View(),
 		GAP,
+		bar,
+		"\n"+status,
 		m.textarea.View(),
 	)
 }
 
+// toggleMarkdown flips markdown rendering on/off and re-renders every
+// assistant turn currently in the viewport against the new setting.
+func (m model) toggleMarkdown() (tea.Model, tea.Cmd) {
+	m.markdown = !m.markdown
+	m.rerenderDisplay()
+	return m, nil
+}
+
+// rerenderDisplay rebuilds m.display for every assistant turn from its raw
+// buffer, honoring the current markdown setting and viewport width. The
+// turn currently streaming (if any) is left alone: its raw buffer is still
+// growing, and streamMsg appends to m.display by concatenation, so rendering
+// it early would get clobbered by the next chunk.
+func (m *model) rerenderDisplay() {
+	streaming := -1
+	if m.waitingForReply && len(m.rawDisplay) > 0 {
+		streaming = len(m.rawDisplay) - 1
+	}
+	for i, raw := range m.rawDisplay {
+		if raw == "" || i == streaming {
+			continue // user turn, or the in-flight reply, nothing to (re-)render
+		}
+		m.display[i] = m.chatStyle.Render("> ") + m.renderReply(raw)
+	}
+	m.viewport.SetContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(strings.Join(m.display, "\n")))
+	m.viewport.GotoBottom()
+}
+
+// renderReply formats a completed assistant reply as markdown (when enabled)
+// sized to the current viewport width, falling back to the raw text.
+func (m *model) renderReply(raw string) string {
+	if !m.markdown {
+		return raw
+	}
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(m.viewport.Width),
+	)
+	if err != nil {
+		return raw
+	}
+	out, err := renderer.Render(raw)
+	if err != nil {
+		return raw
+	}
+	return strings.TrimRight(out, "\n")
+}
+
 // keyEsc handles the escape key press to exit the program
 func (m model) keyEsc() (tea.Model, tea.Cmd) {
+	m.saveConversation()
 	return m, tea.Quit
 }
 
+// keyCtrlC cancels the in-flight stream instead of quitting, since ctrl+c is
+// only reachable here while waitingForReply is true.
+func (m model) keyCtrlC() (tea.Model, tea.Cmd) {
+	if m.cancelling {
+		return m, nil // already cancelling, ignore repeat presses
+	}
+	m.cancelling = true
+	close(m.stopSignal)
+	if m.streamCancel != nil {
+		m.streamCancel()
+	}
+	return m, nil
+}
+
 // errMsg handles error messages
 func (m model) errMsg() (tea.Model, tea.Cmd) {
 	return m, nil
@@ -176,7 +825,8 @@ func (m model) errMsg() (tea.Model, tea.Cmd) {
 func (m *model) windowSizeMsg(msg tea.WindowSizeMsg) {
 	m.viewport.Width = msg.Width
 	m.textarea.SetWidth(msg.Width)
-	m.viewport.Height = msg.Height - m.textarea.Height() - lipgloss.Height(GAP)
+	m.viewport.Height = msg.Height - m.textarea.Height() - lipgloss.Height(GAP) - m.statusbar.Height() - 1
+	m.convList.SetSize(msg.Width, msg.Height)
 
 	if len(m.display) > 0 {
 		m.viewport.SetContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(strings.Join(m.display, "\n")))
@@ -187,49 +837,111 @@ func (m *model) windowSizeMsg(msg tea.WindowSizeMsg) {
 // keyEnter handles the enter key press to send the prompt and stream the response
 func (m model) keyEnter() (tea.Model, tea.Cmd) {
 	prompt := m.textarea.Value() // capture prompt before reset textarea
+	if strings.HasPrefix(prompt, "/") {
+		return m.runCommand(prompt)
+	}
+
 	m.history = append(m.history, genai.NewContentFromText(prompt, genai.RoleUser))
 	m.display = append(m.display, m.chatStyle.Render("? ")+m.promptStyle.Render(m.textarea.Value()))
+	m.rawDisplay = append(m.rawDisplay, "") // user turns are never markdown-rendered
+
+	m.promptHistory = append(m.promptHistory, prompt)
+	m.historyIndex = len(m.promptHistory)
+	m.draftPrompt = ""
+	if err := m.store.AppendPromptHistory(prompt); err != nil {
+		m.log.WriteString(fmt.Sprintf("append prompt history: %v\n", err))
+	}
 	m.viewport.SetContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(strings.Join(m.display, "\n"))) // TODO: why NewStyle?
 
 	index := len(m.display) // capture current index
 	parts := make(chan string)
+	usageCh := make(chan usageStats, 1)
+	stopSignal := make(chan struct{})
+	streamCtx, cancel := context.WithCancel(m.ctx)
 	go func() {
-		stream := m.chat.SendMessageStream(m.ctx, genai.Part{Text: prompt})
+		var usage usageStats
+		defer func() { usageCh <- usage }() // runs after close(parts) below (LIFO); usageCh is buffered, so this never blocks
+		defer close(parts)
+		stream := m.chat.SendMessageStream(streamCtx, genai.Part{Text: prompt})
 		for chunk, _ := range stream {
+			select {
+			case <-stopSignal:
+				return
+			default:
+			}
 			if chunk == nil {
 				continue
 			}
+			if chunk.UsageMetadata != nil {
+				usage = usageStats{
+					promptTokens:    int(chunk.UsageMetadata.PromptTokenCount),
+					candidateTokens: int(chunk.UsageMetadata.CandidatesTokenCount),
+					totalTokens:     int(chunk.UsageMetadata.TotalTokenCount),
+				}
+			}
+			if len(chunk.Candidates) == 0 || chunk.Candidates[0].Content == nil || len(chunk.Candidates[0].Content.Parts) == 0 {
+				continue // usage/finishReason-only chunk, no text to emit
+			}
 			part := chunk.Candidates[0].Content.Parts[0].Text
 			parts <- part // push parts into channel in order
 		}
-		close(parts)
 	}()
 
 	readPart := func() tea.Msg {
 		i := index       // capture current index in closure
 		p, ok := <-parts // closure captures parts channel and current index
 		if !ok {
-			return nil // channel closed
+			return streamDoneMsg{index: i, usage: <-usageCh}
 		}
 		return streamMsg{index: i, part: p}
 	}
 
 	m.textarea.Reset()
+	m.resetTextareaHeight()
 	m.viewport.GotoBottom()
 	m.next = readPart
-	return m, m.next
+	m.stopSignal = stopSignal
+	m.streamCancel = cancel
+	m.waitingForReply = true
+	m.cancelling = false
+	return m, tea.Batch(m.next, m.spinner.Tick)
 }
 
 func (m model) streamMsg(msg streamMsg) (tea.Model, tea.Cmd) {
 	if msg.index == len(m.display) {
 		m.display = append(m.display, m.chatStyle.Render("> ")) // add new entry
+		m.rawDisplay = append(m.rawDisplay, "")
 	}
-	m.display[msg.index] += msg.part
+	m.rawDisplay[msg.index] += msg.part
+	m.display[msg.index] += msg.part // show raw text incrementally while streaming
 	m.viewport.SetContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(strings.Join(m.display, "\n")))
 	m.viewport.GotoBottom()
-	if m.next == nil {
-		return m, nil // read all parts
-	}
-
 	return m, m.next
 }
+
+// streamDoneMsg handles the end of a reply's stream, whether it finished
+// normally or was cancelled via ctrl+c. The completed turn is re-rendered as
+// markdown (if enabled) now that no more chunks are coming.
+func (m model) streamDoneMsg(msg streamDoneMsg) (tea.Model, tea.Cmd) {
+	m.waitingForReply = false
+	m.cancelling = false
+	m.next = nil
+	m.streamCancel = nil
+	m.lastUsage = msg.usage
+	m.sessionPromptTokens += msg.usage.promptTokens
+	m.sessionCandidateTokens += msg.usage.candidateTokens
+	m.sessionCost += m.prices.Cost(m.activeModel, msg.usage.promptTokens, msg.usage.candidateTokens)
+	if msg.index < len(m.rawDisplay) {
+		if reply := m.rawDisplay[msg.index]; reply != "" {
+			// The user turn was appended to m.history in keyEnter; the model's
+			// turn only exists once the stream completes, so it's appended
+			// here to keep m.history alternating for the next recreateChat.
+			m.history = append(m.history, genai.NewContentFromText(reply, genai.RoleModel))
+		}
+		m.display[msg.index] = m.chatStyle.Render("> ") + m.renderReply(m.rawDisplay[msg.index])
+		m.viewport.SetContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(strings.Join(m.display, "\n")))
+		m.viewport.GotoBottom()
+	}
+	m.saveConversation()
+	return m, nil
+}
@@ -0,0 +1,57 @@
+// Package pricing estimates the USD cost of a Gemini call from a per-model
+// price table, configurable via YAML similar to lmcli/mods' model configs.
+package pricing
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModelPrice is the USD cost per million tokens for a single model.
+type ModelPrice struct {
+	InputPerMillionTokens  float64 `yaml:"input_per_million_tokens"`
+	OutputPerMillionTokens float64 `yaml:"output_per_million_tokens"`
+}
+
+// Table maps a model name to its prices.
+type Table map[string]ModelPrice
+
+// Default returns built-in prices for the models this program ships with.
+// Unlisted models cost nothing, rather than guessing.
+func Default() Table {
+	return Table{
+		"gemini-2.5-flash": {InputPerMillionTokens: 0.30, OutputPerMillionTokens: 2.50},
+		"gemini-2.5-pro":   {InputPerMillionTokens: 1.25, OutputPerMillionTokens: 10.00},
+	}
+}
+
+// Load reads a YAML price table from path, overlaying it onto Default.
+// A missing file is not an error; Default is returned unchanged.
+func Load(path string) (Table, error) {
+	table := Default()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return table, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pricing: read %q: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("pricing: decode %q: %w", path, err)
+	}
+	return table, nil
+}
+
+// Cost estimates the USD cost of a call against model, given the prompt and
+// candidate token counts. Unknown models cost 0.
+func (t Table) Cost(model string, promptTokens, candidateTokens int) float64 {
+	price, ok := t[model]
+	if !ok {
+		return 0
+	}
+	return float64(promptTokens)/1_000_000*price.InputPerMillionTokens +
+		float64(candidateTokens)/1_000_000*price.OutputPerMillionTokens
+}
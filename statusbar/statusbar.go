@@ -0,0 +1,40 @@
+// Package statusbar renders the compact token-usage/cost line shown between
+// the chat viewport and the prompt textarea.
+package statusbar
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Model is a lipgloss-styled, single-line status bar.
+type Model struct {
+	style lipgloss.Style
+}
+
+// New returns a Model with the repo's muted status-line styling.
+func New() Model {
+	return Model{style: lipgloss.NewStyle().Foreground(lipgloss.Color("8"))}
+}
+
+// Height is the number of terminal rows the status bar occupies.
+func (m Model) Height() int { return 1 }
+
+// Stats is the set of values a single render needs.
+type Stats struct {
+	PromptTokens    int
+	CandidateTokens int
+	SessionTokens   int
+	Cost            float64
+	SessionCost     float64
+}
+
+// View renders the status bar sized to width.
+func (m Model) View(width int, s Stats) string {
+	text := fmt.Sprintf(
+		"tokens in/out %d/%d · session %d tok · cost $%.4f (session $%.4f)",
+		s.PromptTokens, s.CandidateTokens, s.SessionTokens, s.Cost, s.SessionCost,
+	)
+	return m.style.Width(width).Render(text)
+}
@@ -0,0 +1,193 @@
+// Package store persists chat conversations and prompt history to disk so
+// they can be listed, resumed, renamed, and deleted across runs of the
+// program.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// Conversation is a single saved chat session.
+type Conversation struct {
+	ID                string          `json:"id"`
+	Name              string          `json:"name"`
+	Model             string          `json:"model"`
+	SystemInstruction string          `json:"system_instruction"`
+	History           []*genai.Content `json:"history"`
+	Display           []string        `json:"display"`
+	RawDisplay        []string        `json:"raw_display"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+// Store reads and writes conversations under a directory on disk, one file
+// per conversation plus a shared prompt history file.
+type Store struct {
+	dir string
+}
+
+// New returns a Store rooted at the "gemini" folder of os.UserConfigDir
+// (~/.config/gemini on Linux by default), creating the directory
+// (and its conversations subdirectory) if it does not already exist.
+func New() (*Store, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("store: resolve config dir: %w", err)
+	}
+	dir := filepath.Join(configDir, "gemini")
+	if err := os.MkdirAll(conversationsDir(dir), 0o755); err != nil {
+		return nil, fmt.Errorf("store: create conversations dir: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+func conversationsDir(dir string) string {
+	return filepath.Join(dir, "conversations")
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(conversationsDir(s.dir), id+".json")
+}
+
+// List returns all saved conversations ordered by most recently updated.
+func (s *Store) List() ([]Conversation, error) {
+	entries, err := os.ReadDir(conversationsDir(s.dir))
+	if err != nil {
+		return nil, fmt.Errorf("store: list conversations: %w", err)
+	}
+
+	conversations := make([]Conversation, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		c, err := s.Load(id)
+		if err != nil {
+			continue // skip unreadable/corrupt conversation files
+		}
+		conversations = append(conversations, *c)
+	}
+
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
+	})
+	return conversations, nil
+}
+
+// Load reads a single conversation by id.
+func (s *Store) Load(id string) (*Conversation, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		return nil, fmt.Errorf("store: load conversation %q: %w", id, err)
+	}
+	var c Conversation
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("store: decode conversation %q: %w", id, err)
+	}
+	return &c, nil
+}
+
+// Save writes a conversation to disk, stamping UpdatedAt (and CreatedAt, if
+// unset) before writing.
+func (s *Store) Save(c *Conversation) error {
+	if c.ID == "" {
+		return fmt.Errorf("store: save conversation: missing id")
+	}
+	now := time.Now()
+	if c.CreatedAt.IsZero() {
+		if existing, err := s.Load(c.ID); err == nil {
+			c.CreatedAt = existing.CreatedAt
+		} else {
+			c.CreatedAt = now
+		}
+	}
+	c.UpdatedAt = now
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("store: encode conversation %q: %w", c.ID, err)
+	}
+	if err := os.WriteFile(s.path(c.ID), data, 0o644); err != nil {
+		return fmt.Errorf("store: write conversation %q: %w", c.ID, err)
+	}
+	return nil
+}
+
+// Rename updates a conversation's display name.
+func (s *Store) Rename(id, name string) error {
+	c, err := s.Load(id)
+	if err != nil {
+		return err
+	}
+	c.Name = name
+	return s.Save(c)
+}
+
+// Delete removes a conversation from disk.
+func (s *Store) Delete(id string) error {
+	if err := os.Remove(s.path(id)); err != nil {
+		return fmt.Errorf("store: delete conversation %q: %w", id, err)
+	}
+	return nil
+}
+
+// NewID generates a conversation id from the current time, unique enough for
+// a single-user local store.
+func NewID() string {
+	return fmt.Sprintf("%d", time.Now().UnixNano())
+}
+
+// maxPromptHistory bounds how many prior prompts are kept on disk.
+const maxPromptHistory = 1000
+
+func (s *Store) promptHistoryPath() string {
+	return filepath.Join(s.dir, "prompt_history.json")
+}
+
+// LoadPromptHistory returns previously submitted prompts, oldest first. A
+// missing file is not an error; it just means there's no history yet.
+func (s *Store) LoadPromptHistory() ([]string, error) {
+	data, err := os.ReadFile(s.promptHistoryPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("store: load prompt history: %w", err)
+	}
+	var history []string
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("store: decode prompt history: %w", err)
+	}
+	return history, nil
+}
+
+// AppendPromptHistory records a submitted prompt, trimming the oldest
+// entries once maxPromptHistory is exceeded.
+func (s *Store) AppendPromptHistory(prompt string) error {
+	history, err := s.LoadPromptHistory()
+	if err != nil {
+		return err
+	}
+	history = append(history, prompt)
+	if len(history) > maxPromptHistory {
+		history = history[len(history)-maxPromptHistory:]
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("store: encode prompt history: %w", err)
+	}
+	if err := os.WriteFile(s.promptHistoryPath(), data, 0o644); err != nil {
+		return fmt.Errorf("store: write prompt history: %w", err)
+	}
+	return nil
+}